@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/containers/image/types"
+)
+
+// clientOverrides holds per-reference overrides of the registry client configuration, supplied via an
+// optional "[key=value,...]" prefix on the reference string, e.g.
+// "docker://[auth=/etc/mycreds.json,tls-verify=false]registry.local/foo:tag". This lets a single process
+// talk to several registries, each with its own credentials file or TLS policy, without global
+// SystemContext state.
+type clientOverrides struct {
+	authFilePath string // "auth": path to a containers-auth.json-style credentials file.
+	tlsVerify    *bool  // "tls-verify": "true" or "false"; nil if not overridden.
+}
+
+// empty reports whether overrides has no overrides set at all, i.e. StringWithinTransport should not emit
+// a "[...]" prefix for it.
+func (overrides clientOverrides) empty() bool {
+	return overrides.authFilePath == "" && overrides.tlsVerify == nil
+}
+
+// apply returns a copy of ctx with overrides merged in, taking precedence over whatever ctx already set; ctx
+// itself is not modified. If overrides is empty, ctx is returned unchanged (including a nil ctx).
+func (overrides clientOverrides) apply(ctx *types.SystemContext) *types.SystemContext {
+	if overrides.empty() {
+		return ctx
+	}
+	merged := types.SystemContext{}
+	if ctx != nil {
+		merged = *ctx
+	}
+	if overrides.authFilePath != "" {
+		merged.AuthFilePath = overrides.authFilePath
+	}
+	if overrides.tlsVerify != nil {
+		merged.DockerInsecureSkipTLSVerify = !*overrides.tlsVerify
+	}
+	return &merged
+}
+
+// String returns the "[key=value,...]" prefix reproducing overrides, or "" if overrides is empty.
+func (overrides clientOverrides) String() string {
+	if overrides.empty() {
+		return ""
+	}
+	var parts []string
+	if overrides.authFilePath != "" {
+		parts = append(parts, "auth="+escapeOverrideValue(overrides.authFilePath))
+	}
+	if overrides.tlsVerify != nil {
+		parts = append(parts, "tls-verify="+strconv.FormatBool(*overrides.tlsVerify))
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// escapeOverrideValue escapes "\", "]" and "," in value, the three bytes which are otherwise significant to
+// parseClientOverrides, so that the value round-trips unambiguously through parseOverridesBody.
+func escapeOverrideValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `]`, `\]`, `,`, `\,`)
+	return replacer.Replace(value)
+}
+
+// unescapeOverrideValue reverses escapeOverrideValue.
+func unescapeOverrideValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			b.WriteByte(value[i])
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// indexUnescaped returns the index of the first occurrence of sep in s that is not escaped by a preceding
+// "\", or -1 if there is none. A "\" always escapes the byte that follows it, whether or not that byte is sep.
+func indexUnescaped(s string, sep byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits s on occurrences of sep that are not escaped by a preceding "\", keeping any
+// escape sequences intact in the returned parts (they still need unescapeOverrideValue applied).
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for {
+		i := indexUnescaped(s[start:], sep)
+		if i == -1 {
+			return append(parts, s[start:])
+		}
+		parts = append(parts, s[start:start+i])
+		start += i + 1
+	}
+}
+
+// parseClientOverrides parses the leading "[key=value,...]" prefix of input, if any, and returns the parsed
+// clientOverrides along with the remainder of input with the prefix stripped. If input does not start with
+// "[", it returns a zero clientOverrides and input unchanged.
+//
+// A literal "]", "," or "\" within a value must be escaped as "\]", "\," / "\\"; any other, unescaped "]"
+// closes the prefix. This makes the split between the prefix and the rest of the reference unambiguous,
+// which is what StringWithinTransport relies on to round-trip.
+func parseClientOverrides(input string) (clientOverrides, string, error) {
+	if !strings.HasPrefix(input, "[") {
+		return clientOverrides{}, input, nil
+	}
+	end := indexUnescaped(input[1:], ']')
+	if end == -1 {
+		return clientOverrides{}, "", fmt.Errorf("docker: reference %q has an unterminated [...] prefix", input)
+	}
+	overrides, err := parseOverridesBody(input[1 : 1+end])
+	if err != nil {
+		return clientOverrides{}, "", fmt.Errorf("docker: invalid [...] prefix in reference %q: %v", input, err)
+	}
+	return overrides, input[1+end+1:], nil
+}
+
+// parseOverridesBody parses the comma-separated key=value pairs found inside a "[...]" prefix, where commas
+// escaped within a value (per escapeOverrideValue) do not act as separators. The first unescaped "=" of each
+// pair separates the key from the value; any later "=" is part of the value.
+func parseOverridesBody(body string) (clientOverrides, error) {
+	var overrides clientOverrides
+	if body == "" {
+		return overrides, nil
+	}
+	for _, kv := range splitUnescaped(body, ',') {
+		i := indexUnescaped(kv, '=')
+		if i == -1 {
+			return clientOverrides{}, fmt.Errorf("entry %q is not in key=value form", kv)
+		}
+		key, value := kv[:i], unescapeOverrideValue(kv[i+1:])
+		switch key {
+		case "auth":
+			if value == "" {
+				return clientOverrides{}, fmt.Errorf("empty value for %q", key)
+			}
+			overrides.authFilePath = value
+		case "tls-verify":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return clientOverrides{}, fmt.Errorf("invalid boolean value %q for %q", value, key)
+			}
+			overrides.tlsVerify = &b
+		default:
+			return clientOverrides{}, fmt.Errorf("unknown client-override key %q", key)
+		}
+	}
+	return overrides, nil
+}
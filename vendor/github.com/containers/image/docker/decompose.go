@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"strings"
+
+	"github.com/containers/image/docker/reference"
+)
+
+// DockerRefParts is the result of decomposing a docker reference into its constituent parts, so that
+// callers which only have a string (or a dockerReference) do not need to re-parse it, or poke at
+// ref.ref, to learn what the user actually typed versus what was defaulted.
+//
+// Registry, Name, and exactly one of Tag or Digest are always set to the fully-resolved value, the same one
+// ref.DockerReference().String() would use; Namespace is the path before the last "/", empty if the path
+// has a single component. HasRegistry and HasExplicitTag additionally record whether the input actually
+// spelled those out, as opposed to them being filled in by the docker.io/library/…:latest normalization
+// applied by ParseReference. HasExplicitTag is only ever true when Tag is set, so it is false for a
+// reference carrying a digest, even if the input also spelled out a tag.
+type DockerRefParts struct {
+	Registry       string
+	Namespace      string
+	Name           string
+	Tag            string
+	Digest         string
+	HasRegistry    bool
+	HasExplicitTag bool
+}
+
+// Decompose returns the DockerRefParts of ref.
+func (ref dockerReference) Decompose() DockerRefParts {
+	path := reference.Path(ref.ref)
+	namespace, name := "", path
+	if i := strings.LastIndex(path, "/"); i != -1 {
+		namespace, name = path[:i], path[i+1:]
+	}
+	parts := DockerRefParts{
+		Registry:    reference.Domain(ref.ref),
+		Namespace:   namespace,
+		Name:        name,
+		HasRegistry: ref.explicitRegistry,
+	}
+	// A reference can be both NamedTagged and Canonical at the same time (see ParseReferenceLax), but
+	// ref.ref.String() only ever prints the digest in that case; mirror that priority here, the same way
+	// tagOrDigest does, so Decompose and ReferenceString stay consistent with the rest of the package.
+	if digested, ok := ref.ref.(reference.Canonical); ok {
+		parts.Digest = digested.Digest().String()
+	} else if tagged, ok := ref.ref.(reference.NamedTagged); ok {
+		parts.Tag = tagged.Tag()
+		parts.HasExplicitTag = ref.explicitTag
+	}
+	return parts
+}
+
+// Decompose parses refString as a docker reference (in the same "//"-prefixed form ParseReference expects)
+// and returns its DockerRefParts. Like ParseReferenceLax, it does not reject a reference carrying both a
+// tag and a digest, since Decompose only reports on the input, it does not act on it.
+func Decompose(refString string) (DockerRefParts, error) {
+	ref, err := ParseReferenceLax(refString)
+	if err != nil {
+		return DockerRefParts{}, err
+	}
+	return ref.(dockerReference).Decompose(), nil
+}
+
+// ReferenceString reassembles parts into the canonical reference string it was decomposed from, e.g.
+// "docker.io/library/busybox:latest" decomposes and reassembles as "busybox:latest".
+func (parts DockerRefParts) ReferenceString() string {
+	name := parts.Name
+	if parts.Namespace != "" && !(parts.Registry == "docker.io" && parts.Namespace == "library") {
+		name = parts.Namespace + "/" + name
+	}
+	if parts.Registry != "docker.io" {
+		name = parts.Registry + "/" + name
+	}
+	if parts.Tag != "" {
+		name += ":" + parts.Tag
+	}
+	if parts.Digest != "" {
+		name += "@" + parts.Digest
+	}
+	return name
+}
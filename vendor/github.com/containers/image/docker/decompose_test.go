@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecomposeReassembleRoundTrip(t *testing.T) {
+	for _, c := range validReferenceTestCases {
+		parts, err := Decompose("//" + c.input)
+		require.NoError(t, err, c.input)
+		assert.Equal(t, c.dockerRef, parts.ReferenceString(), c.input)
+	}
+}
+
+func TestDecomposeHasExplicitTag(t *testing.T) {
+	parts, err := Decompose("//busybox")
+	require.NoError(t, err)
+	assert.False(t, parts.HasExplicitTag)
+	assert.Equal(t, "latest", parts.Tag)
+
+	parts, err = Decompose("//busybox:latest")
+	require.NoError(t, err)
+	assert.True(t, parts.HasExplicitTag)
+	assert.Equal(t, "latest", parts.Tag)
+}
+
+func TestDecomposeHasRegistry(t *testing.T) {
+	parts, err := Decompose("//busybox")
+	require.NoError(t, err)
+	assert.False(t, parts.HasRegistry)
+	assert.Equal(t, "docker.io", parts.Registry)
+
+	parts, err = Decompose("//docker.io/library/busybox")
+	require.NoError(t, err)
+	assert.True(t, parts.HasRegistry)
+	assert.Equal(t, "docker.io", parts.Registry)
+
+	parts, err = Decompose("//example.com/ns/foo:bar")
+	require.NoError(t, err)
+	assert.True(t, parts.HasRegistry)
+	assert.Equal(t, "example.com", parts.Registry)
+	assert.Equal(t, "ns", parts.Namespace)
+	assert.Equal(t, "foo", parts.Name)
+}
+
+func TestDecomposeDigest(t *testing.T) {
+	parts, err := Decompose("//busybox" + sha256digest)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:"+sha256digestHex, parts.Digest)
+	assert.Equal(t, "", parts.Tag)
+	assert.False(t, parts.HasExplicitTag)
+}
+
+// TestDecomposeTagAndDigestPrefersDigest covers the one case this whole series exists for: a reference
+// which is simultaneously NamedTagged and Canonical (see ParseReferenceLax). Decompose must give the digest
+// the same priority tagOrDigest does, so ReferenceString reassembles to the same string ref.ref.String()
+// would produce, rather than "name:tag@digest".
+func TestDecomposeTagAndDigestPrefersDigest(t *testing.T) {
+	input := "busybox:latest" + sha256digest
+
+	ref, err := ParseReferenceLax("//" + input)
+	require.NoError(t, err)
+	dockerRef, ok := ref.(dockerReference)
+	require.True(t, ok)
+	assert.True(t, dockerRef.explicitTag, "tag was spelled out explicitly in the input")
+
+	parts := dockerRef.Decompose()
+	assert.Equal(t, "sha256:"+sha256digestHex, parts.Digest)
+	assert.Equal(t, "", parts.Tag)
+	assert.False(t, parts.HasExplicitTag)
+	assert.Equal(t, dockerRef.ref.String(), parts.ReferenceString())
+
+	parts, err = Decompose("//" + input)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:"+sha256digestHex, parts.Digest)
+	assert.Equal(t, "", parts.Tag)
+	assert.Equal(t, "busybox"+sha256digest, parts.ReferenceString())
+}
@@ -0,0 +1,227 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/image/docker/policyconfiguration"
+	"github.com/containers/image/docker/reference"
+	"github.com/containers/image/image"
+	"github.com/containers/image/transports"
+	"github.com/containers/image/types"
+)
+
+func init() {
+	transports.Register(Transport)
+}
+
+// Transport is an ImageTransport for Docker registry-hosted images.
+var Transport = dockerTransport{}
+
+type dockerTransport struct{}
+
+func (t dockerTransport) Name() string {
+	return "docker"
+}
+
+// ParseReference converts a string, which should not start with the ImageTransport.Name prefix, into an ImageReference.
+func (t dockerTransport) ParseReference(reference string) (types.ImageReference, error) {
+	return ParseReference(reference)
+}
+
+// ValidatePolicyConfigurationScope checks that scope is a valid name for a signature.PolicyTransportScopes keys
+func (t dockerTransport) ValidatePolicyConfigurationScope(scope string) error {
+	return policyconfiguration.ValidateScope(scope)
+}
+
+// dockerReference is an ImageReference for Docker images.
+type dockerReference struct {
+	ref reference.Named // By construction we know that !reference.IsNameOnly(ref)
+
+	// explicitRegistry and explicitTag record whether the input this reference was built from spelled
+	// out a registry / a tag, as opposed to ref having one filled in by normalization; see Decompose.
+	explicitRegistry bool
+	explicitTag      bool
+
+	// overrides holds the per-reference registry client configuration parsed from an optional "[...]"
+	// prefix on the reference string; see clientOverrides.
+	overrides clientOverrides
+}
+
+// ParseReference converts a string, which should not start with the ImageTransport.Name prefix, into a Docker
+// ImageReference.
+//
+// References which are simultaneously a reference.NamedTagged and a reference.Canonical are rejected: the
+// docker registry API has no way to push or pull by both a tag and a digest at once, so keeping one of the two
+// values around silently is surprising and has in the past hidden user typos. Callers which depend on the
+// previous tag-is-dropped behavior (e.g. using a tag+digest reference as a pull-time hint) should use
+// ParseReferenceLax instead.
+//
+// An unqualified name (e.g. "busybox") is always resolved against docker.io, without consulting any
+// configured search registries; callers which want unqualified names resolved the way podman/buildah do
+// should use ParseReferenceCandidates instead, and either try its candidates in order or, for
+// single-registry use cases, take just its first element.
+//
+// refString may additionally start with a "[key=value,...]" prefix overriding the registry client
+// configuration (credentials file, TLS verification, …) used for just this reference; see clientOverrides.
+func ParseReference(refString string) (types.ImageReference, error) {
+	return parseReference(refString, false)
+}
+
+// ParseReferenceLax is equivalent to ParseReference, except that references which have both a tag and a digest
+// are accepted, with the tag silently ignored, matching the historical behavior of this package. This exists
+// only for compatibility with callers which rely on a tag+digest reference as a pull-time lookup hint; new
+// code should use ParseReference.
+func ParseReferenceLax(refString string) (types.ImageReference, error) {
+	return parseReference(refString, true)
+}
+
+func parseReference(refString string, allowTagAndDigest bool) (types.ImageReference, error) {
+	if !strings.HasPrefix(refString, "//") {
+		return nil, fmt.Errorf("docker: image reference %s does not start with //", refString)
+	}
+	trimmed := strings.TrimPrefix(refString, "//")
+
+	overrides, trimmed, err := parseClientOverrides(trimmed)
+	if err != nil {
+		return nil, err
+	}
+
+	name, suffix := splitNameTagDigest(trimmed)
+	explicitRegistry := !isUnqualifiedName(name)
+	explicitTag := strings.HasPrefix(suffix, ":")
+	// reference.ParseNamed keeps only the digest of a tag+digest input, so check the input string itself.
+	if !allowTagAndDigest && explicitTag && strings.Contains(suffix, "@") {
+		return nil, fmt.Errorf("Docker references with both a tag and a digest are not supported: %s (use ParseReferenceLax/NewReferenceLax to keep only the digest)", trimmed)
+	}
+
+	ref, err := reference.ParseNamed(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.TagNameOnly(ref)
+
+	dockerRef, err := newReference(ref, allowTagAndDigest, explicitRegistry, explicitTag)
+	if err != nil {
+		return nil, err
+	}
+	dockerRef.overrides = overrides
+	return dockerRef, nil
+}
+
+// NewReference returns a Docker reference for a named reference. The reference must satisfy !reference.IsNameOnly(),
+// and, unless the caller knows a tag and a digest can be handled, must not be both a reference.NamedTagged and a
+// reference.Canonical; see ParseReference for the rationale. Use NewReferenceLax if that compatibility behavior is
+// required.
+//
+// Because ref was already fully assembled by the caller, it is treated as though the registry and the tag (or
+// digest) were both spelled out explicitly; see Decompose for why that distinction matters. NewReference never
+// sets client overrides; use ParseReference for that.
+func NewReference(ref reference.Named) (types.ImageReference, error) {
+	dockerRef, err := newReference(ref, false, true, true)
+	if err != nil {
+		return nil, err
+	}
+	return dockerRef, nil
+}
+
+// NewReferenceLax is equivalent to NewReference, but accepts references which are both a reference.NamedTagged and
+// a reference.Canonical, silently ignoring the tag. See ParseReferenceLax for when this is appropriate.
+func NewReferenceLax(ref reference.Named) (types.ImageReference, error) {
+	dockerRef, err := newReference(ref, true, true, true)
+	if err != nil {
+		return nil, err
+	}
+	return dockerRef, nil
+}
+
+func newReference(ref reference.Named, allowTagAndDigest, explicitRegistry, explicitTag bool) (dockerReference, error) {
+	if reference.IsNameOnly(ref) {
+		return dockerReference{}, fmt.Errorf("Docker reference %s has neither a tag nor a digest", ref.String())
+	}
+	if !allowTagAndDigest {
+		if _, isTagged := ref.(reference.NamedTagged); isTagged {
+			if _, isDigested := ref.(reference.Canonical); isDigested {
+				return dockerReference{}, fmt.Errorf("Docker references with both a tag and a digest are not supported: %s (use ParseReferenceLax/NewReferenceLax to keep only the digest)", ref.String())
+			}
+		}
+	}
+	return dockerReference{ref: ref, explicitRegistry: explicitRegistry, explicitTag: explicitTag}, nil
+}
+
+func (ref dockerReference) Transport() types.ImageTransport {
+	return Transport
+}
+
+// StringWithinTransport returns a string representation of the reference, which MUST be such that
+// reference.Transport().ParseReference(reference.StringWithinTransport()) returns an equivalent reference.
+func (ref dockerReference) StringWithinTransport() string {
+	return "//" + ref.overrides.String() + ref.ref.String()
+}
+
+func (ref dockerReference) DockerReference() reference.Named {
+	return ref.ref
+}
+
+// PolicyConfigurationIdentity returns a string representation of the reference, suitable for policy lookup.
+func (ref dockerReference) PolicyConfigurationIdentity() string {
+	res, err := policyconfiguration.DockerReferenceIdentity(ref.ref)
+	if res == "" { // Coverage: Should never happen, NewReference above should refuse such references.
+		panic(fmt.Sprintf("Internal inconsistency: policyconfiguration.DockerReferenceIdentity returned %#v, %v", res, err))
+	}
+	return res
+}
+
+// PolicyConfigurationNamespaces returns a list of other policy configuration namespaces to search
+// for if explicit configuration for PolicyConfigurationIdentity is not set. The list will be processed
+// in order, terminating on first match, and an implicit "" is always checked at the end.
+func (ref dockerReference) PolicyConfigurationNamespaces() []string {
+	return policyconfiguration.DockerReferenceNamespaces(ref.ref)
+}
+
+// NewImage returns a types.Image for this reference, possibly specialized for this ImageTransport.
+// The caller must call .Close() on the returned Image.
+func (ref dockerReference) NewImage(ctx *types.SystemContext) (types.Image, error) {
+	src, err := newImageSource(ref.overrides.apply(ctx), ref)
+	if err != nil {
+		return nil, err
+	}
+	return image.FromSource(src)
+}
+
+// NewImageSource returns a types.ImageSource for this reference.
+// The caller must call .Close() on the returned ImageSource.
+func (ref dockerReference) NewImageSource(ctx *types.SystemContext, requestedManifestMIMETypes []string) (types.ImageSource, error) {
+	return newImageSource(ref.overrides.apply(ctx), ref)
+}
+
+// NewImageDestination returns a types.ImageDestination for this reference.
+// The caller must call .Close() on the returned ImageDestination.
+//
+// Pushing can only ever honor a tag or a digest, never both at once, so this is rejected here even for
+// references built via NewReferenceLax / ParseReferenceLax.
+func (ref dockerReference) NewImageDestination(ctx *types.SystemContext) (types.ImageDestination, error) {
+	if _, isTagged := ref.ref.(reference.NamedTagged); isTagged {
+		if _, isDigested := ref.ref.(reference.Canonical); isDigested {
+			return nil, fmt.Errorf("Docker reference %s has both a tag and a digest; can't push to it", ref.ref.String())
+		}
+	}
+	return newImageDestination(ref.overrides.apply(ctx), ref)
+}
+
+// DeleteImage deletes the named image from the registry, if supported.
+func (ref dockerReference) DeleteImage(ctx *types.SystemContext) error {
+	return deleteImage(ref.overrides.apply(ctx), ref)
+}
+
+// tagOrDigest returns a tag or digest from the reference.
+func (ref dockerReference) tagOrDigest() (string, error) {
+	if ref, ok := ref.ref.(reference.Canonical); ok {
+		return ref.Digest().String(), nil
+	}
+	if ref, ok := ref.ref.(reference.NamedTagged); ok {
+		return ref.Tag(), nil
+	}
+	// This should not happen, NewReference above should refuse such references.
+	return "", fmt.Errorf("Internal inconsistency: Reference %s unexpectedly has neither a digest nor a tag", ref.ref.String())
+}
@@ -46,14 +46,11 @@ func testParseReference(t *testing.T, fn func(string) (types.ImageReference, err
 		{"//busybox:notlatest", "busybox:notlatest"},           // Explicit tag
 		{"//busybox" + sha256digest, "busybox" + sha256digest}, // Explicit digest
 		{"//busybox", "busybox:latest"},                        // Default tag
-		// A github.com/distribution/reference value can have a tag and a digest at the same time!
-		// github.com/docker/reference handles that by dropping the tag. That is not obviously the
-		// right thing to do, but it is at least reasonable, so test that we keep behaving reasonably.
-		// This test case should not be construed to make this an API promise.
-		// FIXME? Instead work extra hard to reject such input?
-		{"//busybox:latest" + sha256digest, "busybox" + sha256digest}, // Both tag and digest
-		{"//docker.io/library/busybox:latest", "busybox:latest"},      // All implied values explicitly specified
-		{"//UPPERCASEISINVALID", ""},                                  // Invalid input
+		// A github.com/distribution/reference value can have a tag and a digest at the same time; by
+		// default we reject that rather than silently dropping the tag. See TestParseReferenceLax.
+		{"//busybox:latest" + sha256digest, ""},
+		{"//docker.io/library/busybox:latest", "busybox:latest"}, // All implied values explicitly specified
+		{"//UPPERCASEISINVALID", ""},                             // Invalid input
 	} {
 		ref, err := fn(c.input)
 		if c.expected == "" {
@@ -67,6 +64,26 @@ func testParseReference(t *testing.T, fn func(string) (types.ImageReference, err
 	}
 }
 
+func TestParseReferenceLax(t *testing.T) {
+	// Unlike ParseReference, ParseReferenceLax keeps accepting a tag+digest reference, dropping the tag.
+	ref, err := ParseReferenceLax("//busybox:latest" + sha256digest)
+	require.NoError(t, err)
+	dockerRef, ok := ref.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "busybox"+sha256digest, dockerRef.ref.String())
+
+	// Everything ParseReference accepts, ParseReferenceLax accepts identically.
+	ref, err = ParseReferenceLax("//busybox:notlatest")
+	require.NoError(t, err)
+	dockerRef, ok = ref.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "busybox:notlatest", dockerRef.ref.String())
+
+	// Still rejects input without the // prefix.
+	_, err = ParseReferenceLax("busybox")
+	assert.Error(t, err)
+}
+
 // refWithTagAndDigest is a reference.NamedTagged and reference.Canonical at the same time.
 type refWithTagAndDigest struct{ reference.Canonical }
 
@@ -107,6 +124,25 @@ func TestNewReference(t *testing.T) {
 	tagDigestRef := refWithTagAndDigest{refDigested}
 	_, err = NewReference(tagDigestRef)
 	assert.Error(t, err)
+
+	// NewReferenceLax accepts the same tag+digest value, keeping only the digest.
+	laxRef, err := NewReferenceLax(tagDigestRef)
+	require.NoError(t, err)
+	dockerRef, ok := laxRef.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "busybox"+sha256digest, dockerRef.ref.String())
+}
+
+func TestReferenceNewImageDestinationRejectsTagAndDigest(t *testing.T) {
+	parsed, err := reference.ParseNamed("busybox" + sha256digest)
+	require.NoError(t, err)
+	refDigested, ok := parsed.(reference.Canonical)
+	require.True(t, ok)
+	ref, err := NewReferenceLax(refWithTagAndDigest{refDigested})
+	require.NoError(t, err)
+
+	_, err = ref.NewImageDestination(&types.SystemContext{RegistriesDirPath: "/this/doesnt/exist"})
+	assert.Error(t, err)
 }
 
 func TestReferenceTransport(t *testing.T) {
@@ -129,6 +165,80 @@ func TestReferenceStringWithinTransport(t *testing.T) {
 	}
 }
 
+func TestReferenceStringWithinTransportClientOverrides(t *testing.T) {
+	for _, c := range []struct{ input, stringWithinTransport string }{
+		{"[auth=/etc/mycreds.json]registry.local/foo:tag", "//[auth=/etc/mycreds.json]registry.local/foo:tag"},
+		{"[tls-verify=false]registry.local/foo:tag", "//[tls-verify=false]registry.local/foo:tag"},
+		{"[auth=/etc/mycreds.json,tls-verify=false]registry.local/foo:tag", "//[auth=/etc/mycreds.json,tls-verify=false]registry.local/foo:tag"},
+	} {
+		ref, err := ParseReference("//" + c.input)
+		require.NoError(t, err, c.input)
+		stringRef := ref.StringWithinTransport()
+		assert.Equal(t, c.stringWithinTransport, stringRef, c.input)
+		// Round-trip through ParseReference again to verify stability.
+		ref2, err := Transport.ParseReference(stringRef)
+		require.NoError(t, err, c.input)
+		assert.Equal(t, stringRef, ref2.StringWithinTransport(), c.input)
+	}
+}
+
+func TestReferenceStringWithinTransportClientOverridesWithComma(t *testing.T) {
+	// A comma inside an override value must not be mistaken for the key=value separator: it has to be
+	// escaped as "\," on the way in, the same way escapeOverrideValue would escape it on the way out.
+	ref, err := ParseReference(`//[auth=/etc/my\,creds.json]registry.local/foo:tag`)
+	require.NoError(t, err)
+	dockerRef, ok := ref.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "/etc/my,creds.json", dockerRef.overrides.authFilePath)
+
+	stringRef := ref.StringWithinTransport()
+	ref2, err := Transport.ParseReference(stringRef)
+	require.NoError(t, err)
+	dockerRef2, ok := ref2.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "/etc/my,creds.json", dockerRef2.overrides.authFilePath)
+	assert.Equal(t, stringRef, ref2.StringWithinTransport())
+}
+
+func TestParseReferenceClientOverrides(t *testing.T) {
+	ref, err := ParseReference("//[auth=/etc/mycreds.json,tls-verify=false]registry.local/foo:tag")
+	require.NoError(t, err)
+	dockerRef, ok := ref.(dockerReference)
+	require.True(t, ok)
+	assert.Equal(t, "/etc/mycreds.json", dockerRef.overrides.authFilePath)
+	require.NotNil(t, dockerRef.overrides.tlsVerify)
+	assert.False(t, *dockerRef.overrides.tlsVerify)
+	assert.Equal(t, "registry.local/foo:tag", dockerRef.ref.String())
+
+	// No prefix at all: empty overrides, unaffected parsing.
+	ref, err = ParseReference("//busybox")
+	require.NoError(t, err)
+	dockerRef, ok = ref.(dockerReference)
+	require.True(t, ok)
+	assert.True(t, dockerRef.overrides.empty())
+
+	// An unescaped "]" inside a value is not rejected by the client-overrides parser itself: it simply
+	// closes the prefix at that point, same as any other "]" would. Here that happens to still produce an
+	// error, but only because the leftover "b]/busybox" is not valid reference syntax; parseClientOverrides
+	// has no ambiguity check of its own. If reference syntax is ever relaxed to allow "]", this case would
+	// start succeeding (with a likely-unintended auth path), so a real check belongs in
+	// parseClientOverrides if that "reject ambiguous inputs" guarantee needs to hold on its own.
+	_, err = ParseReference("//[auth=a]b]/busybox")
+	assert.Error(t, err)
+
+	// Invalid key.
+	_, err = ParseReference("//[bogus=1]busybox")
+	assert.Error(t, err)
+
+	// Invalid boolean value.
+	_, err = ParseReference("//[tls-verify=maybe]busybox")
+	assert.Error(t, err)
+
+	// Unterminated prefix.
+	_, err = ParseReference("//[auth=/etc/mycreds.json")
+	assert.Error(t, err)
+}
+
 func TestReferenceDockerReference(t *testing.T) {
 	for _, c := range validReferenceTestCases {
 		ref, err := ParseReference("//" + c.input)
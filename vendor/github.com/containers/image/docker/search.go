@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containers/image/types"
+)
+
+// defaultRegistriesConfPath is the default path consulted for unqualified-name resolution, mirroring the
+// location podman and buildah already read registries.conf from.
+const defaultRegistriesConfPath = "/etc/containers/registries.conf"
+
+// registriesConf is the subset of registries.conf relevant to unqualified-name resolution.
+type registriesConf struct {
+	Registries struct {
+		Search struct {
+			// Registries is an ordered list of registry hostnames (optionally with a port or a
+			// path prefix) consulted, in order, to qualify an unqualified input name.
+			Registries []string `toml:"registries"`
+		} `toml:"search"`
+	} `toml:"registries"`
+	// Aliases maps an unqualified short name (e.g. "ubi8") directly to a fully-qualified name
+	// (e.g. "registry.access.redhat.com/ubi8:latest"), bypassing the search list entirely.
+	Aliases map[string]string `toml:"aliases"`
+}
+
+// registriesConfPath returns the registries.conf path to use for sys.
+func registriesConfPath(sys *types.SystemContext) string {
+	if sys != nil && sys.SystemRegistriesConfPath != "" {
+		return sys.SystemRegistriesConfPath
+	}
+	return defaultRegistriesConfPath
+}
+
+// loadRegistriesConf loads the registries configuration from path. A missing file is treated as an empty
+// configuration (no search registries, no aliases), matching the current behavior of plain docker.io
+// resolution when no configuration has been installed.
+func loadRegistriesConf(path string) (registriesConf, error) {
+	var conf registriesConf
+	_, err := toml.DecodeFile(path, &conf)
+	if err != nil && os.IsNotExist(err) {
+		return conf, nil
+	}
+	return conf, err
+}
+
+// splitNameTagDigest splits input into a bare name and a suffix which is either empty, or starts with ':'
+// (a tag, possibly itself followed by "@digest") or '@' (a digest alone), using only the last path
+// component to decide where the name ends, so that a ':' in a registry host:port is not mistaken for a tag
+// separator. When a name carries both a tag and a digest (e.g. "busybox:latest@sha256:…"), the suffix
+// starts at the tag, not the digest, so callers can still tell the tag was spelled out explicitly.
+func splitNameTagDigest(input string) (name, suffix string) {
+	rest := input
+	if slash := strings.LastIndexByte(input, '/'); slash != -1 {
+		rest = input[slash+1:]
+	}
+	colon := strings.IndexByte(rest, ':')
+	at := strings.IndexByte(rest, '@')
+	cutInRest := -1
+	switch {
+	case colon != -1 && (at == -1 || colon < at):
+		cutInRest = colon
+	case at != -1:
+		cutInRest = at
+	}
+	if cutInRest == -1 {
+		return input, ""
+	}
+	cut := len(input) - len(rest) + cutInRest
+	return input[:cut], input[cut:]
+}
+
+// isUnqualifiedName reports whether name has no registry hostname component, using the same heuristic as
+// Docker's own reference normalization: the part before the first '/' is a hostname only if it contains a
+// '.' or a ':', or is exactly "localhost"; otherwise the whole name is relative to the default registry.
+func isUnqualifiedName(name string) bool {
+	i := strings.IndexRune(name, '/')
+	if i == -1 {
+		return true
+	}
+	host := name[:i]
+	return !strings.ContainsAny(host, ".:") && host != "localhost"
+}
+
+// ParseReferenceCandidates resolves input, which may be an unqualified short name (e.g. "busybox"), a
+// short name qualified by a search registry alias (e.g. "ubi8"), or an already-qualified name (e.g.
+// "docker.io/library/busybox:latest"), into an ordered list of candidate references to try, consulting the
+// [registries.search] registries and [aliases] table of the registries.conf found at
+// sys.SystemRegistriesConfPath (or defaultRegistriesConfPath if unset).
+//
+// Already-qualified input, and input matching an alias, resolve to a single candidate. Otherwise the
+// returned candidates are one per configured search registry, in order, followed by a docker.io fallback.
+//
+// Callers which only ever talk to a single registry can keep using ParseReference, which is equivalent to
+// using the first element of the slice ParseReferenceCandidates would return for already-qualified input.
+func ParseReferenceCandidates(sys *types.SystemContext, input string) ([]types.ImageReference, error) {
+	conf, err := loadRegistriesConf(registriesConfPath(sys))
+	if err != nil {
+		return nil, fmt.Errorf("docker: error loading registries configuration: %v", err)
+	}
+
+	name, suffix := splitNameTagDigest(input)
+
+	if target, ok := conf.Aliases[name]; ok {
+		if suffix != "" {
+			targetName, _ := splitNameTagDigest(target)
+			target = targetName + suffix
+		}
+		ref, err := ParseReference("//" + target)
+		if err != nil {
+			return nil, fmt.Errorf("docker: invalid alias target %q for %q: %v", target, name, err)
+		}
+		return []types.ImageReference{ref}, nil
+	}
+
+	if !isUnqualifiedName(name) {
+		ref, err := ParseReference("//" + input)
+		if err != nil {
+			return nil, err
+		}
+		return []types.ImageReference{ref}, nil
+	}
+
+	registries := conf.Registries.Search.Registries
+	candidates := make([]types.ImageReference, 0, len(registries)+1)
+	for _, registry := range registries {
+		ref, err := ParseReference("//" + registry + "/" + input)
+		if err != nil {
+			return nil, fmt.Errorf("docker: invalid search registry %q for %q: %v", registry, input, err)
+		}
+		candidates = append(candidates, ref)
+	}
+	// Always fall back to docker.io, so that ParseReferenceCandidates behaves like ParseReference when no
+	// search registries are configured.
+	dockerIoRef, err := ParseReference("//" + input)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, dockerIoRef)
+	return candidates, nil
+}
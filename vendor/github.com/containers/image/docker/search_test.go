@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/containers/image/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRegistriesConf = `
+[registries.search]
+registries = ["registry1.example.com", "registry2.example.com:5000"]
+
+[aliases]
+"ubi8" = "registry.access.redhat.com/ubi8:latest"
+`
+
+func TestParseReferenceCandidates(t *testing.T) {
+	f, err := ioutil.TempFile("", "registries.conf")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(testRegistriesConf)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sys := &types.SystemContext{SystemRegistriesConfPath: f.Name()}
+
+	for _, c := range []struct {
+		input    string
+		expected []string
+	}{
+		{ // Bare, unqualified name: one candidate per search registry, then the docker.io fallback.
+			"busybox",
+			[]string{
+				"//registry1.example.com/busybox:latest",
+				"//registry2.example.com:5000/busybox:latest",
+				"//busybox:latest",
+			},
+		},
+		{ // Unqualified name with an explicit tag.
+			"busybox:notlatest",
+			[]string{
+				"//registry1.example.com/busybox:notlatest",
+				"//registry2.example.com:5000/busybox:notlatest",
+				"//busybox:notlatest",
+			},
+		},
+		{ // Unqualified name with a digest.
+			"busybox" + sha256digest,
+			[]string{
+				"//registry1.example.com/busybox" + sha256digest,
+				"//registry2.example.com:5000/busybox" + sha256digest,
+				"//busybox" + sha256digest,
+			},
+		},
+		{ // Already-qualified name: a single candidate, search registries are not consulted.
+			"example.com/ns/foo:bar",
+			[]string{"//example.com/ns/foo:bar"},
+		},
+		{ // Alias match: a single candidate, resolved directly, search registries are not consulted.
+			"ubi8",
+			[]string{"//registry.access.redhat.com/ubi8:latest"},
+		},
+		{ // Alias match with an explicit tag: the alias's own tag is replaced, not appended.
+			"ubi8:8.5",
+			[]string{"//registry.access.redhat.com/ubi8:8.5"},
+		},
+	} {
+		refs, err := ParseReferenceCandidates(sys, c.input)
+		require.NoError(t, err, c.input)
+		actual := make([]string, len(refs))
+		for i, ref := range refs {
+			actual[i] = ref.StringWithinTransport()
+		}
+		assert.Equal(t, c.expected, actual, c.input)
+	}
+}
+
+func TestParseReferenceCandidatesNoConfig(t *testing.T) {
+	// A nonexistent registries.conf is treated as an empty configuration: no search registries, no
+	// aliases, so unqualified names resolve to docker.io alone, just like ParseReference.
+	sys := &types.SystemContext{SystemRegistriesConfPath: "/this/does/not/exist/registries.conf"}
+	refs, err := ParseReferenceCandidates(sys, "busybox")
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, "//busybox:latest", refs[0].StringWithinTransport())
+}
+
+func TestSplitNameTagDigest(t *testing.T) {
+	for _, c := range []struct{ input, name, suffix string }{
+		{"busybox", "busybox", ""},
+		{"busybox:notlatest", "busybox", ":notlatest"},
+		{"busybox" + sha256digest, "busybox", sha256digest},
+		// Both a tag and a digest: the suffix must start at the tag, not the digest, so that callers
+		// computing explicitTag from strings.HasPrefix(suffix, ":") see the tag was spelled out.
+		{"busybox:latest" + sha256digest, "busybox", ":latest" + sha256digest},
+		{"registry.example.com:5000/ns/busybox:latest", "registry.example.com:5000/ns/busybox", ":latest"},
+	} {
+		name, suffix := splitNameTagDigest(c.input)
+		assert.Equal(t, c.name, name, c.input)
+		assert.Equal(t, c.suffix, suffix, c.input)
+	}
+}